@@ -0,0 +1,148 @@
+package gorethink
+
+import (
+	"encoding/json"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// defaultBatchSize is used by RunWriteBatch when batchSize is <= 0 and the
+// opts do not carry enough information to auto-tune a size.
+const defaultBatchSize = 200
+
+// RunWriteBatch behaves like RunWrite but, for an Insert/Update/Replace term
+// whose first argument is an array of documents, splits that array into
+// sub-batches of batchSize documents and runs each sub-batch sequentially,
+// merging the resulting WriteResponses into one. This avoids exceeding the
+// server's query size limit and reduces peak memory usage when bulk-loading
+// large slices of documents.
+//
+// If batchSize is <= 0 it is derived from RunOpts.MaxBatchRows or
+// RunOpts.MaxBatchBytes (measuring the encoded size of the first document),
+// falling back to a sane default.
+//
+// If a sub-batch fails the merged partial response is returned along with
+// the error so callers can still recover the generated keys and counts for
+// the sub-batches that succeeded.
+//
+//	res, err := r.Table("table").Insert(docs).RunWriteBatch(sess, 500)
+func (t Term) RunWriteBatch(s *Session, batchSize int, optArgs ...RunOpts) (WriteResponse, error) {
+	docs, docsIdx, ok := t.batchableDocs()
+	if !ok {
+		return t.RunWrite(s, optArgs...)
+	}
+
+	if batchSize <= 0 {
+		batchSize = autoBatchSize(docs, optArgs...)
+	}
+
+	var merged WriteResponse
+
+	for start := 0; start < len(docs); start += batchSize {
+		end := start + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		batchTerm := t
+		batchTerm.args = append([]Term{}, t.args...)
+		batchTerm.args[docsIdx] = Term{
+			name:     t.args[docsIdx].name,
+			termType: p.Term_MAKE_ARRAY,
+			args:     docs[start:end],
+		}
+
+		res, err := batchTerm.RunWrite(s, optArgs...)
+		mergeWriteResponse(&merged, res)
+		if err != nil {
+			return merged, err
+		}
+	}
+
+	return merged, nil
+}
+
+// batchableDocs returns the documents of t's MAKE_ARRAY argument, along with
+// the index of that argument within t.args, if t is an Insert, Update or
+// Replace term. It returns false otherwise.
+//
+// The document array is not t's first argument: for a term like
+// r.Table("t").Insert(docs), t.args[0] is the receiver (the Table term) and
+// t.args[1] is the MAKE_ARRAY of documents, matching the layout Term.String
+// relies on (t.args[0].String() for the receiver, t.args[1:] for the call's
+// own arguments).
+func (t Term) batchableDocs() ([]Term, int, bool) {
+	switch t.termType {
+	case p.Term_INSERT, p.Term_UPDATE, p.Term_REPLACE:
+	default:
+		return nil, 0, false
+	}
+
+	if len(t.args) < 2 || t.args[1].termType != p.Term_MAKE_ARRAY {
+		return nil, 0, false
+	}
+
+	return t.args[1].args, 1, true
+}
+
+// autoBatchSize derives a batch size from the first RunOpts' MaxBatchRows or
+// MaxBatchBytes, measuring the encoded size of the first document to convert
+// a byte budget into a row count.
+func autoBatchSize(docs []Term, optArgs ...RunOpts) int {
+	if len(optArgs) == 0 || len(docs) == 0 {
+		return defaultBatchSize
+	}
+
+	opts := optArgs[0]
+
+	if rows, ok := opts.MaxBatchRows.(int); ok && rows > 0 {
+		return rows
+	}
+
+	maxBytes, ok := opts.MaxBatchBytes.(int)
+	if !ok || maxBytes <= 0 {
+		return defaultBatchSize
+	}
+
+	built, err := docs[0].build()
+	if err != nil {
+		return defaultBatchSize
+	}
+
+	encoded, err := json.Marshal(built)
+	if err != nil || len(encoded) == 0 {
+		return defaultBatchSize
+	}
+
+	if n := maxBytes / len(encoded); n > 0 {
+		return n
+	}
+
+	return defaultBatchSize
+}
+
+// mergeWriteResponse accumulates src into dst, summing counts, concatenating
+// slices and keeping the first non-empty FirstError.
+func mergeWriteResponse(dst *WriteResponse, src WriteResponse) {
+	dst.Errors += src.Errors
+	dst.Inserted += src.Inserted
+	dst.Updated += src.Updated
+	dst.Unchanged += src.Unchanged
+	dst.Replaced += src.Replaced
+	dst.Renamed += src.Renamed
+	dst.Skipped += src.Skipped
+	dst.Deleted += src.Deleted
+	dst.Created += src.Created
+	dst.DBsCreated += src.DBsCreated
+	dst.TablesCreated += src.TablesCreated
+	dst.Dropped += src.Dropped
+	dst.DBsDropped += src.DBsDropped
+	dst.TablesDropped += src.TablesDropped
+	dst.GeneratedKeys = append(dst.GeneratedKeys, src.GeneratedKeys...)
+	dst.Changes = append(dst.Changes, src.Changes...)
+	dst.ConfigChanges = append(dst.ConfigChanges, src.ConfigChanges...)
+
+	if dst.FirstError == "" {
+		dst.FirstError = src.FirstError
+	}
+}
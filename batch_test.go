@@ -0,0 +1,102 @@
+package gorethink
+
+import (
+	"testing"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+func docTerm(id int) Term {
+	return Term{termType: p.Term_DATUM, data: map[string]interface{}{"id": id}}
+}
+
+func insertTerm(docs ...Term) Term {
+	return Term{
+		termType: p.Term_INSERT,
+		args: []Term{
+			{termType: p.Term_TABLE, name: "Table"},
+			{termType: p.Term_MAKE_ARRAY, args: docs},
+		},
+	}
+}
+
+func TestBatchableDocs(t *testing.T) {
+	docs := []Term{docTerm(1), docTerm(2), docTerm(3)}
+	term := insertTerm(docs...)
+
+	got, idx, ok := term.batchableDocs()
+	if !ok {
+		t.Fatal("expected batchableDocs to report ok for an Insert over a MAKE_ARRAY")
+	}
+	if idx != 1 {
+		t.Fatalf("expected the document array at arg index 1, got %d", idx)
+	}
+	if len(got) != len(docs) {
+		t.Fatalf("expected %d docs, got %d", len(docs), len(got))
+	}
+}
+
+func TestBatchableDocsNonBatchable(t *testing.T) {
+	cases := []Term{
+		// Not an Insert/Update/Replace term.
+		{termType: p.Term_GET, args: []Term{{termType: p.Term_TABLE}, {termType: p.Term_DATUM, data: "id"}}},
+		// Insert, but the call argument isn't a MAKE_ARRAY (e.g. a single doc).
+		{termType: p.Term_INSERT, args: []Term{{termType: p.Term_TABLE}, {termType: p.Term_DATUM, data: map[string]interface{}{}}}},
+	}
+
+	for i, term := range cases {
+		if _, _, ok := term.batchableDocs(); ok {
+			t.Fatalf("case %d: expected batchableDocs to report false", i)
+		}
+	}
+}
+
+func TestMergeWriteResponse(t *testing.T) {
+	dst := WriteResponse{Inserted: 1, GeneratedKeys: []string{"a"}}
+	src := WriteResponse{Inserted: 2, Errors: 1, FirstError: "boom", GeneratedKeys: []string{"b"}}
+
+	mergeWriteResponse(&dst, src)
+
+	if dst.Inserted != 3 {
+		t.Fatalf("expected Inserted to sum to 3, got %d", dst.Inserted)
+	}
+	if dst.Errors != 1 {
+		t.Fatalf("expected Errors to be 1, got %d", dst.Errors)
+	}
+	if dst.FirstError != "boom" {
+		t.Fatalf("expected FirstError to be carried over from src, got %q", dst.FirstError)
+	}
+	if len(dst.GeneratedKeys) != 2 || dst.GeneratedKeys[0] != "a" || dst.GeneratedKeys[1] != "b" {
+		t.Fatalf("expected GeneratedKeys to be concatenated in order, got %v", dst.GeneratedKeys)
+	}
+}
+
+func TestMergeWriteResponseKeepsFirstError(t *testing.T) {
+	dst := WriteResponse{FirstError: "first"}
+	mergeWriteResponse(&dst, WriteResponse{FirstError: "second"})
+
+	if dst.FirstError != "first" {
+		t.Fatalf("expected FirstError to stay %q, got %q", "first", dst.FirstError)
+	}
+}
+
+func TestAutoBatchSizeDefault(t *testing.T) {
+	if n := autoBatchSize([]Term{docTerm(1)}); n != defaultBatchSize {
+		t.Fatalf("expected default batch size with no opts, got %d", n)
+	}
+}
+
+func TestAutoBatchSizeFromMaxBatchRows(t *testing.T) {
+	n := autoBatchSize([]Term{docTerm(1)}, RunOpts{MaxBatchRows: 50})
+	if n != 50 {
+		t.Fatalf("expected MaxBatchRows to be honoured, got %d", n)
+	}
+}
+
+func TestAutoBatchSizeFromMaxBatchBytes(t *testing.T) {
+	docs := []Term{docTerm(1)}
+	n := autoBatchSize(docs, RunOpts{MaxBatchBytes: 1000})
+	if n <= 0 {
+		t.Fatalf("expected a positive batch size, got %d", n)
+	}
+}
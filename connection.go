@@ -0,0 +1,166 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// Connection wraps a single network connection to a RethinkDB server. A
+// query is written as a 12-byte header (an 8-byte token followed by a
+// 4-byte little-endian body length) followed by the JSON-encoded query body;
+// responses are framed the same way. Connection multiplexes queries over the
+// connection by token, dispatching each response to the Cursor waiting on
+// it.
+type Connection struct {
+	conn net.Conn
+
+	mu      sync.Mutex
+	cursors map[int64]*Cursor
+}
+
+// newConnection wraps conn, starting the background read loop that
+// dispatches incoming responses to their Cursor by token.
+func newConnection(conn net.Conn) *Connection {
+	c := &Connection{
+		conn:    conn,
+		cursors: map[int64]*Cursor{},
+	}
+
+	go c.readLoop()
+
+	return c
+}
+
+// sendQuery writes q to the connection and returns a Cursor over its
+// responses, blocking until the first batch has arrived. watchCancel keeps
+// watching ctx for the cursor's entire lifetime (not just this initial
+// wait): if ctx is done at any point before the cursor is closed, a STOP
+// query is sent for q.Token, any pending read is unblocked and the cursor is
+// dropped from c.cursors.
+func (c *Connection) sendQuery(ctx context.Context, q Query) (*Cursor, error) {
+	cur := newCursor(c, q.Token, q.Term)
+
+	c.mu.Lock()
+	c.cursors[q.Token] = cur
+	c.mu.Unlock()
+
+	if err := c.write(q); err != nil {
+		c.removeCursor(q.Token)
+		return nil, err
+	}
+
+	go c.watchCancel(ctx, cur, q.Token)
+
+	err := cur.awaitFirstResponse(ctx)
+	if err != nil {
+		c.removeCursor(q.Token)
+		return nil, err
+	}
+
+	return cur, nil
+}
+
+// watchCancel watches ctx on behalf of cur for as long as cur stays open. If
+// ctx is done first, it sends a STOP for token, cancels cur and removes it
+// from c.cursors; if cur.Close is called first it exits without sending
+// anything further. It is started once per sendQuery call and is the only
+// thing that unblocks a cursor whose context is cancelled after its first
+// response has already arrived (e.g. mid-changefeed).
+func (c *Connection) watchCancel(ctx context.Context, cur *Cursor, token int64) {
+	select {
+	case <-ctx.Done():
+		cur.cancel(ctx.Err())
+		_ = c.write(Query{Type: p.Query_STOP, Token: token})
+		c.removeCursor(token)
+	case <-cur.closeCh:
+	}
+}
+
+func (c *Connection) removeCursor(token int64) {
+	c.mu.Lock()
+	delete(c.cursors, token)
+	c.mu.Unlock()
+}
+
+// write encodes q and writes its framed representation to the connection.
+func (c *Connection) write(q Query) error {
+	body, err := json.Marshal(q.build())
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header[:8], uint64(q.Token))
+	binary.LittleEndian.PutUint32(header[8:], uint32(len(body)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+
+	_, err = c.conn.Write(body)
+	return err
+}
+
+// readLoop reads framed responses off the connection for the connection's
+// lifetime, dispatching each to the Cursor registered for its token. It
+// returns, cancelling every outstanding Cursor with the read error, once the
+// connection is closed or a read fails.
+func (c *Connection) readLoop() {
+	header := make([]byte, 12)
+
+	for {
+		if _, err := io.ReadFull(c.conn, header); err != nil {
+			c.cancelAll(err)
+			return
+		}
+
+		token := int64(binary.LittleEndian.Uint64(header[:8]))
+		size := binary.LittleEndian.Uint32(header[8:])
+
+		body := make([]byte, size)
+		if _, err := io.ReadFull(c.conn, body); err != nil {
+			c.cancelAll(err)
+			return
+		}
+
+		c.mu.Lock()
+		cur := c.cursors[token]
+		c.mu.Unlock()
+
+		if cur == nil {
+			continue
+		}
+
+		cur.deliver(body)
+	}
+}
+
+// cancelAll aborts every outstanding Cursor with err, used when the
+// connection itself fails or is closed.
+func (c *Connection) cancelAll(err error) {
+	c.mu.Lock()
+	cursors := make([]*Cursor, 0, len(c.cursors))
+	for _, cur := range c.cursors {
+		cursors = append(cursors, cur)
+	}
+	c.mu.Unlock()
+
+	for _, cur := range cursors {
+		cur.cancel(err)
+	}
+}
+
+// Close closes the underlying network connection, aborting every
+// outstanding Cursor with the resulting error.
+func (c *Connection) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,138 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// fakeFrame is a single frame read off a connection using the same 12-byte
+// header framing Connection uses to write queries.
+type fakeFrame struct {
+	token int64
+	body  []byte
+}
+
+// fakeServer is the server side of a net.Pipe, used to drive a Connection
+// end-to-end in tests without a real database.
+type fakeServer struct {
+	conn net.Conn
+}
+
+func newFakeServer() (*fakeServer, *Connection) {
+	client, server := net.Pipe()
+	return &fakeServer{conn: server}, newConnection(client)
+}
+
+func (f *fakeServer) readFrame() (fakeFrame, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(f.conn, header); err != nil {
+		return fakeFrame{}, err
+	}
+
+	token := int64(binary.LittleEndian.Uint64(header[:8]))
+	size := binary.LittleEndian.Uint32(header[8:])
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(f.conn, body); err != nil {
+		return fakeFrame{}, err
+	}
+
+	return fakeFrame{token: token, body: body}, nil
+}
+
+func (f *fakeServer) writeResponse(token int64, resp rawResponse) error {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 12)
+	binary.LittleEndian.PutUint64(header[:8], uint64(token))
+	binary.LittleEndian.PutUint32(header[8:], uint32(len(body)))
+
+	if _, err := f.conn.Write(header); err != nil {
+		return err
+	}
+	_, err = f.conn.Write(body)
+	return err
+}
+
+// TestConnectionCancelSendsStopForCursorLifetime verifies that cancelling a
+// query's context after its first response has already arrived still sends
+// a STOP for its token and unregisters its cursor, i.e. that the cancel
+// watch isn't torn down once the initial wait is over.
+func TestConnectionCancelSendsStopForCursorLifetime(t *testing.T) {
+	server, conn := newFakeServer()
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	type result struct {
+		cur *Cursor
+		err error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		cur, err := conn.sendQuery(ctx, Query{Type: p.Query_START, Token: 42})
+		resultCh <- result{cur, err}
+	}()
+
+	frame, err := server.readFrame()
+	if err != nil {
+		t.Fatalf("unexpected error reading query frame: %v", err)
+	}
+	if frame.token != 42 {
+		t.Fatalf("expected token 42, got %d", frame.token)
+	}
+
+	if err := server.writeResponse(42, rawResponse{Type: responseTypeSuccessPartial, Results: []json.RawMessage{json.RawMessage(`1`)}}); err != nil {
+		t.Fatalf("unexpected error writing response: %v", err)
+	}
+
+	res := <-resultCh
+	if res.err != nil {
+		t.Fatalf("unexpected error from sendQuery: %v", res.err)
+	}
+
+	// Drain the first (and only) buffered result so the cursor looks idle,
+	// mirroring a consumer waiting on the next batch of a changefeed.
+	if _, err := res.cur.nextRaw(ctx); err != nil {
+		t.Fatalf("unexpected error draining first batch: %v", err)
+	}
+
+	cancel()
+
+	stopFrame, err := server.readFrame()
+	if err != nil {
+		t.Fatalf("unexpected error reading stop frame: %v", err)
+	}
+	if stopFrame.token != 42 {
+		t.Fatalf("expected STOP for token 42, got %d", stopFrame.token)
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		conn.mu.Lock()
+		_, stillRegistered := conn.cursors[42]
+		conn.mu.Unlock()
+		if !stillRegistered {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for cursor to be removed after cancellation")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if _, err := res.cur.nextRaw(context.Background()); err != context.Canceled {
+		t.Fatalf("expected context.Canceled from a cancelled cursor, got %v", err)
+	}
+}
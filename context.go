@@ -0,0 +1,89 @@
+package gorethink
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunContext runs a query using the given connection, much like Run, but
+// accepts a context.Context that is threaded through Session.newQueryContext
+// and Session.QueryContext down to the connection's read/write loop. When ctx is done
+// a STOP query is sent for the cursor's token and any pending read on the
+// connection is unblocked, returning ctx.Err().
+//
+//	rows, err := query.RunContext(ctx, sess)
+//	if err != nil {
+//		// error
+//	}
+//
+//  var doc MyDocumentType
+//	for rows.Next(&doc) {
+//      // Do something with document
+//	}
+func (t Term) RunContext(ctx context.Context, s *Session, optArgs ...RunOpts) (*Cursor, error) {
+	opts := map[string]interface{}{}
+	streaming := true
+	if len(optArgs) >= 1 {
+		opts = optArgs[0].toMap()
+		if optArgs[0].Streaming != nil {
+			streaming = *optArgs[0].Streaming
+		}
+	}
+
+	q, err := s.newQueryContext(ctx, t, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	cur.streaming = streaming
+
+	return cur, nil
+}
+
+// RunWriteContext is the context-aware equivalent of RunWrite, see RunContext
+// for details on how ctx cancels the in-flight query.
+//
+//	res, err := r.DB("database").Table("table").Insert(doc).RunWriteContext(ctx, sess)
+func (t Term) RunWriteContext(ctx context.Context, s *Session, optArgs ...RunOpts) (WriteResponse, error) {
+	var response WriteResponse
+
+	res, err := t.RunContext(ctx, s, optArgs...)
+	if err != nil {
+		return response, err
+	}
+	defer res.Close()
+
+	if err = res.OneContext(ctx, &response); err != nil {
+		return response, err
+	}
+
+	if response.Errors > 0 {
+		return response, fmt.Errorf("%s", response.FirstError)
+	}
+
+	return response, nil
+}
+
+// ExecContext is the context-aware equivalent of Exec, see RunContext for
+// details on how ctx cancels the in-flight query.
+//
+//	err := r.DB("database").Table("table").Insert(doc).ExecContext(ctx, sess, r.ExecOpts{
+//		NoReply: true,
+//	})
+func (t Term) ExecContext(ctx context.Context, s *Session, optArgs ...ExecOpts) error {
+	opts := map[string]interface{}{}
+	if len(optArgs) >= 1 {
+		opts = optArgs[0].toMap()
+	}
+
+	q, err := s.newQueryContext(ctx, t, opts)
+	if err != nil {
+		return err
+	}
+
+	return s.ExecContext(ctx, q)
+}
@@ -0,0 +1,67 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+func runContextStreaming(t *testing.T, optArgs ...RunOpts) bool {
+	t.Helper()
+
+	server, conn := newFakeServer()
+	defer conn.Close()
+	sess := &Session{conn: conn}
+
+	term := Term{termType: p.Term_TABLE, name: "Table", rootTerm: true}
+
+	resultCh := make(chan *Cursor, 1)
+	go func() {
+		cur, err := term.RunContext(context.Background(), sess, optArgs...)
+		if err != nil {
+			t.Errorf("unexpected error from RunContext: %v", err)
+		}
+		resultCh <- cur
+	}()
+
+	frame, err := server.readFrame()
+	if err != nil {
+		t.Fatalf("unexpected error reading query frame: %v", err)
+	}
+	if err := server.writeResponse(frame.token, rawResponse{Type: responseTypeSuccessSequence, Results: []json.RawMessage{json.RawMessage(`1`)}}); err != nil {
+		t.Fatalf("unexpected error writing response: %v", err)
+	}
+
+	cur := <-resultCh
+	return cur.streaming
+}
+
+// TestRunContextStreamingDefaultsTrue covers the zero-RunOpts case, which was
+// never broken, but pins down the baseline the other cases are compared
+// against.
+func TestRunContextStreamingDefaultsTrue(t *testing.T) {
+	if !runContextStreaming(t) {
+		t.Fatal("expected streaming to default to true with no RunOpts")
+	}
+}
+
+// TestRunContextStreamingSurvivesUnrelatedOpts guards against the bug where
+// RunOpts.Streaming was a plain bool: its zero value (false) was
+// indistinguishable from an explicit opt-out, so setting any other RunOpts
+// field silently disabled streaming. With Streaming as a *bool this must
+// stay on.
+func TestRunContextStreamingSurvivesUnrelatedOpts(t *testing.T) {
+	if !runContextStreaming(t, RunOpts{MaxBatchBytes: 1024}) {
+		t.Fatal("expected streaming to stay enabled when unrelated RunOpts fields are set")
+	}
+}
+
+// TestRunContextStreamingExplicitOptOut checks that Bool(false) still turns
+// streaming off when requested.
+func TestRunContextStreamingExplicitOptOut(t *testing.T) {
+	if runContextStreaming(t, RunOpts{Streaming: Bool(false)}) {
+		t.Fatal("expected streaming to be disabled when Streaming is explicitly Bool(false)")
+	}
+}
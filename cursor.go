@@ -0,0 +1,327 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// rawResponse mirrors the subset of a RethinkDB protocol response needed to
+// drive a Cursor: a response type and, for SUCCESS_* types, the batch of
+// results as raw JSON so that each document can be decoded lazily.
+type rawResponse struct {
+	Type    int               `json:"t"`
+	Results []json.RawMessage `json:"r"`
+	Error   string            `json:"e,omitempty"`
+}
+
+const (
+	responseTypeSuccessAtom     = 1
+	responseTypeSuccessSequence = 2
+	responseTypeSuccessPartial  = 3
+)
+
+// Cursor is an iterator over the results of a query, returned by Term.Run
+// and Term.RunContext. Documents are buffered a batch at a time as they
+// arrive from the Connection and decoded lazily as they are consumed.
+type Cursor struct {
+	conn  *Connection
+	token int64
+	term  *Term
+
+	// streaming controls whether NextContext decodes a buffered result
+	// directly into dest, or first round-trips it through a generic
+	// interface{} as the pre-streaming decoder did. See RunOpts.Streaming.
+	streaming bool
+
+	mu      sync.Mutex
+	buffer  []json.RawMessage
+	notify  chan struct{}
+	done    bool
+	closed  bool
+	lastErr error
+
+	firstOnce sync.Once
+	gotFirst  chan struct{}
+	firstErr  error
+
+	// closeCh is closed by Close, and is watched for the cursor's entire
+	// lifetime by Connection.watchCancel alongside the query's own context
+	// so that the cancel watcher stops exactly when the cursor does.
+	closeCh chan struct{}
+}
+
+// newCursor creates a Cursor for token, registered against conn. Streaming
+// defaults to true; Term.RunContext overrides it from RunOpts.Streaming.
+func newCursor(conn *Connection, token int64, term *Term) *Cursor {
+	return &Cursor{
+		conn:      conn,
+		token:     token,
+		term:      term,
+		streaming: true,
+		notify:    make(chan struct{}, 1),
+		gotFirst:  make(chan struct{}),
+		closeCh:   make(chan struct{}),
+	}
+}
+
+// signal wakes any goroutine blocked in nextRaw without blocking itself.
+func (c *Cursor) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// awaitFirstResponse blocks until the first batch has been delivered, the
+// cursor has been cancelled, or ctx is done.
+func (c *Cursor) awaitFirstResponse(ctx context.Context) error {
+	select {
+	case <-c.gotFirst:
+		return c.firstErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver is called by the Connection's read loop with the raw body of a
+// single response for this cursor's token.
+func (c *Cursor) deliver(body []byte) {
+	var resp rawResponse
+	err := json.Unmarshal(body, &resp)
+
+	c.mu.Lock()
+	if err != nil {
+		c.lastErr = err
+		c.done = true
+	} else if resp.Error != "" {
+		c.lastErr = errors.New(resp.Error)
+		c.done = true
+	} else {
+		c.buffer = append(c.buffer, resp.Results...)
+		if resp.Type != responseTypeSuccessPartial {
+			c.done = true
+		}
+	}
+	c.mu.Unlock()
+	c.signal()
+
+	c.completeFirst(err)
+}
+
+// cancel aborts any pending or future read on the cursor with err. It is
+// used both when the connection fails and when a query's context is done.
+func (c *Cursor) cancel(err error) {
+	c.mu.Lock()
+	if c.lastErr == nil {
+		c.lastErr = err
+	}
+	c.done = true
+	c.mu.Unlock()
+	c.signal()
+
+	c.completeFirst(err)
+}
+
+// completeFirst records err as the outcome of the first response and closes
+// gotFirst, unblocking awaitFirstResponse. It is called from both deliver
+// and cancel, each of which may legitimately be the first to complete a
+// cursor (e.g. a changefeed's second SUCCESS_PARTIAL batch arriving after
+// the consumer already drained the first), so the close must only ever
+// happen once; firstOnce guarantees that regardless of which one wins the
+// race.
+func (c *Cursor) completeFirst(err error) {
+	c.firstOnce.Do(func() {
+		c.firstErr = err
+		close(c.gotFirst)
+	})
+}
+
+// nextRaw blocks until the next buffered result is available, more data has
+// arrived from the connection, the cursor is exhausted (io.EOF), or ctx is
+// done.
+func (c *Cursor) nextRaw(ctx context.Context) (json.RawMessage, error) {
+	for {
+		c.mu.Lock()
+		if len(c.buffer) > 0 {
+			raw := c.buffer[0]
+			c.buffer = c.buffer[1:]
+			c.mu.Unlock()
+			return raw, nil
+		}
+		if c.done {
+			err := c.lastErr
+			c.mu.Unlock()
+			if err == nil {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Err returns the error, if any, that terminated iteration. It returns nil
+// if the cursor was simply exhausted.
+func (c *Cursor) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastErr
+}
+
+// Close closes the cursor, stopping Connection.watchCancel's watch over it,
+// releasing its registration on the Connection and, if the cursor hadn't
+// already run to completion (or been cancelled), sending a STOP for its
+// token so the server-side query is actually torn down. It is safe to call
+// Close multiple times and on an already-exhausted cursor.
+func (c *Cursor) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	alreadyDone := c.done
+	c.mu.Unlock()
+
+	close(c.closeCh)
+	c.conn.removeCursor(c.token)
+
+	if !alreadyDone {
+		return c.conn.write(Query{Type: p.Query_STOP, Token: c.token})
+	}
+
+	return nil
+}
+
+// peekFirstRaw returns the first buffered result, if any, without consuming
+// it. It is used to let Session.QueryContext decode a write query's
+// WriteResponse for its QueryHooks without disturbing the result the caller
+// (e.g. RunWriteContext) will go on to read via NextContext/OneContext.
+func (c *Cursor) peekFirstRaw() (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.buffer) == 0 {
+		return nil, false
+	}
+
+	return c.buffer[0], true
+}
+
+// Next blocks until the next result is available and decodes it into dest,
+// returning false once the cursor is exhausted or an error occurs; use Err
+// to distinguish between the two. Next is a thin wrapper around NextContext
+// using context.Background.
+func (c *Cursor) Next(dest interface{}) bool {
+	return c.NextContext(context.Background(), dest)
+}
+
+// NextContext is the context-aware equivalent of Next. If ctx is done before
+// the next result arrives it returns false and Err reports ctx.Err().
+func (c *Cursor) NextContext(ctx context.Context, dest interface{}) bool {
+	raw, err := c.nextRaw(ctx)
+	if err != nil {
+		if err != io.EOF {
+			c.mu.Lock()
+			if c.lastErr == nil {
+				c.lastErr = err
+			}
+			c.mu.Unlock()
+		}
+		return false
+	}
+
+	if !c.streaming {
+		// Round-trip through a generic value, mirroring the two-pass decode
+		// used before streaming support was added.
+		var generic interface{}
+		if err := json.Unmarshal(raw, &generic); err != nil {
+			c.setErr(err)
+			return false
+		}
+
+		reencoded, err := json.Marshal(generic)
+		if err != nil {
+			c.setErr(err)
+			return false
+		}
+
+		raw = reencoded
+	}
+
+	if err := json.Unmarshal(raw, dest); err != nil {
+		c.setErr(err)
+		return false
+	}
+
+	return true
+}
+
+func (c *Cursor) setErr(err error) {
+	c.mu.Lock()
+	if c.lastErr == nil {
+		c.lastErr = err
+	}
+	c.mu.Unlock()
+}
+
+// All drains the cursor into dest, which must be a pointer to a slice. All
+// is a thin wrapper around AllContext using context.Background.
+func (c *Cursor) All(dest interface{}) error {
+	return c.AllContext(context.Background(), dest)
+}
+
+// AllContext is the context-aware equivalent of All.
+func (c *Cursor) AllContext(ctx context.Context, dest interface{}) error {
+	destPtr := reflect.ValueOf(dest)
+	if destPtr.Kind() != reflect.Ptr || destPtr.Elem().Kind() != reflect.Slice {
+		return errors.New("gorethink: results argument must be a pointer to a slice")
+	}
+
+	sliceVal := destPtr.Elem()
+	elemType := sliceVal.Type().Elem()
+
+	for {
+		elem := reflect.New(elemType)
+		if !c.NextContext(ctx, elem.Interface()) {
+			break
+		}
+		sliceVal = reflect.Append(sliceVal, elem.Elem())
+	}
+	destPtr.Elem().Set(sliceVal)
+
+	return c.Err()
+}
+
+// One scans the first result into dest and closes the cursor. It returns
+// io.EOF if the cursor has no results. One is a thin wrapper around
+// OneContext using context.Background.
+func (c *Cursor) One(dest interface{}) error {
+	return c.OneContext(context.Background(), dest)
+}
+
+// OneContext is the context-aware equivalent of One.
+func (c *Cursor) OneContext(ctx context.Context, dest interface{}) error {
+	defer c.Close()
+
+	if !c.NextContext(ctx, dest) {
+		if err := c.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DecodeNext blocks until the next document is available, waiting for the
+// server's next batch once the buffered one is used up, and decodes it directly into dst, without first round-tripping
+// it through a generic map[string]interface{} value. It returns io.EOF once
+// the cursor is exhausted, or ctx.Err() if the cursor was opened with a now
+// expired/cancelled context.
+//
+// DecodeNext is unaffected by RunOpts.Streaming (it always decodes directly
+// into dst); Streaming instead controls the decode path used by Next.
+func (c *Cursor) DecodeNext(dst interface{}) error {
+	return c.DecodeNextContext(context.Background(), dst)
+}
+
+// DecodeNextContext is the context-aware equivalent of DecodeNext.
+func (c *Cursor) DecodeNextContext(ctx context.Context, dst interface{}) error {
+	raw, err := c.nextRaw(ctx)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, dst)
+}
@@ -0,0 +1,76 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func partialBatch(docs ...int) []byte {
+	raw := make([]json.RawMessage, len(docs))
+	for i, d := range docs {
+		b, _ := json.Marshal(d)
+		raw[i] = b
+	}
+	body, _ := json.Marshal(rawResponse{Type: responseTypeSuccessPartial, Results: raw})
+	return body
+}
+
+// TestCursorDeliverSecondBatchDoesNotPanic guards against a cursor whose
+// consumer drains the first SUCCESS_PARTIAL batch before the second one
+// arrives, which used to panic with "close of closed channel" because
+// deliver re-derived "is this the first batch" from the buffer's current
+// length rather than tracking it explicitly.
+func TestCursorDeliverSecondBatchDoesNotPanic(t *testing.T) {
+	cur := newCursor(nil, 1, nil)
+
+	cur.deliver(partialBatch(1))
+
+	if err := cur.awaitFirstResponse(context.Background()); err != nil {
+		t.Fatalf("unexpected error awaiting first response: %v", err)
+	}
+
+	// Drain the first batch so the buffer is empty again before the second
+	// batch is delivered.
+	if _, err := cur.nextRaw(context.Background()); err != nil {
+		t.Fatalf("unexpected error reading first document: %v", err)
+	}
+
+	cur.deliver(partialBatch(2))
+
+	raw, err := cur.nextRaw(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error reading second document: %v", err)
+	}
+
+	var got int
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("unexpected error decoding second document: %v", err)
+	}
+	if got != 2 {
+		t.Fatalf("expected second document to be 2, got %d", got)
+	}
+}
+
+// TestCursorCancelAfterFirstResponse verifies that cancelling a cursor that
+// has already received its first response still unblocks any reader and
+// records the error, exercising the same completeFirst path from the other
+// side of the race described above.
+func TestCursorCancelAfterFirstResponse(t *testing.T) {
+	cur := newCursor(nil, 1, nil)
+
+	cur.deliver(partialBatch(1))
+	if err := cur.awaitFirstResponse(context.Background()); err != nil {
+		t.Fatalf("unexpected error awaiting first response: %v", err)
+	}
+
+	if _, err := cur.nextRaw(context.Background()); err != nil {
+		t.Fatalf("unexpected error reading first document: %v", err)
+	}
+
+	cur.cancel(context.Canceled)
+
+	if _, err := cur.nextRaw(context.Background()); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package gorethinkotel provides a gorethink.QueryHook that records an
+// OpenTelemetry span for every query run through a Session.
+package gorethinkotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	r "gopkg.in/dancannon/gorethink.v1"
+)
+
+// Hook is a r.QueryHook that starts a span per query using the given tracer.
+// Use NewHook to construct one.
+type Hook struct {
+	tracer trace.Tracer
+}
+
+// NewHook returns a Hook that records spans using the named tracer. If
+// tracerName is empty it defaults to this package's import path.
+func NewHook(tracerName string) *Hook {
+	if tracerName == "" {
+		tracerName = "gopkg.in/dancannon/gorethink.v1/gorethinkotel"
+	}
+
+	return &Hook{tracer: otel.Tracer(tracerName)}
+}
+
+type spanKey struct{}
+
+// BeforeQuery starts a span named "gorethink.query" with the query string
+// recorded as the db.statement attribute and the token as gorethink.token.
+func (h *Hook) BeforeQuery(ctx context.Context, ev *r.QueryEvent) context.Context {
+	ctx, span := h.tracer.Start(ctx, "gorethink.query",
+		trace.WithAttributes(
+			attribute.Int64("gorethink.token", ev.Token),
+			attribute.String("gorethink.query_type", ev.Type),
+			attribute.String("db.statement", ev.String()),
+		),
+	)
+
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// AfterQuery ends the span started in BeforeQuery, recording ev.Err as the
+// span status if the query failed.
+func (h *Hook) AfterQuery(ctx context.Context, ev *r.QueryEvent) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if ev.Err != nil {
+		span.RecordError(ev.Err)
+		span.SetStatus(codes.Error, ev.Err.Error())
+	}
+}
@@ -0,0 +1,61 @@
+// Package gorethinkprom provides a gorethink.QueryHook that exports
+// Prometheus metrics for every query run through a Session.
+package gorethinkprom
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	r "gopkg.in/dancannon/gorethink.v1"
+)
+
+// Hook is a r.QueryHook that records a counter and a latency histogram per
+// query, labelled by query type and error class. Use NewHook to construct
+// one with its metrics already registered.
+type Hook struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHook creates a Hook and registers its metrics with reg. Passing a nil
+// reg registers against prometheus.DefaultRegisterer.
+func NewHook(reg prometheus.Registerer) *Hook {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	h := &Hook{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "gorethink",
+			Name:      "queries_total",
+			Help:      "Total number of queries run, labelled by query type and error class.",
+		}, []string{"query_type", "error"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "gorethink",
+			Name:      "query_duration_seconds",
+			Help:      "Query latency in seconds, labelled by query type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"query_type"}),
+	}
+
+	reg.MustRegister(h.queries, h.duration)
+
+	return h
+}
+
+// BeforeQuery is a no-op, timing is derived from ev.StartTime/ev.EndTime in
+// AfterQuery.
+func (h *Hook) BeforeQuery(ctx context.Context, ev *r.QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery records the query's outcome and latency.
+func (h *Hook) AfterQuery(ctx context.Context, ev *r.QueryEvent) {
+	errClass := "none"
+	if ev.Err != nil {
+		errClass = "error"
+	}
+
+	h.queries.WithLabelValues(ev.Type, errClass).Inc()
+	h.duration.WithLabelValues(ev.Type).Observe(ev.Elapsed().Seconds())
+}
@@ -1,6 +1,7 @@
 package gorethink
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -218,13 +219,33 @@ type RunOpts struct {
 	MaxBatchBytes             interface{} `gorethink:"max_batch_bytes,omitempty"`
 	MaxBatchSeconds           interface{} `gorethink:"max_batch_seconds,omitempty"`
 	FirstBatchScaledownFactor interface{} `gorethink:"first_batch_scaledown_factor,omitempty"`
+
+	// Streaming controls whether the Cursor returned by Run decodes each
+	// document straight from its batch into the caller's destination, rather
+	// than first round-tripping it through a generic interface{} value. It
+	// is a client-side option and is never sent to the server.
+	//
+	// Streaming is a *bool, not a bool, so that "not set" can be told apart
+	// from "explicitly disabled": Run defaults to streaming unless Streaming
+	// is non-nil and points at false, so setting any other RunOpts field
+	// (DB, Profile, MaxBatchBytes, ...) doesn't silently turn streaming off.
+	// Use r.Bool(false) to disable it explicitly.
+	Streaming *bool `gorethink:"-"`
+}
+
+// Bool returns a pointer to b, for use with RunOpts.Streaming.
+func Bool(b bool) *bool {
+	return &b
 }
 
 func (o *RunOpts) toMap() map[string]interface{} {
 	return optArgsToMap(o)
 }
 
-// Run runs a query using the given connection.
+// Run runs a query using the given connection. Run is a thin wrapper around
+// RunContext using context.Background, it does not support cancellation. Any
+// QueryHooks registered on s via Session.AddQueryHook are invoked before the
+// query is sent and after the first response is received.
 //
 //	rows, err := query.Run(sess)
 //	if err != nil {
@@ -236,17 +257,7 @@ func (o *RunOpts) toMap() map[string]interface{} {
 //      // Do something with document
 //	}
 func (t Term) Run(s *Session, optArgs ...RunOpts) (*Cursor, error) {
-	opts := map[string]interface{}{}
-	if len(optArgs) >= 1 {
-		opts = optArgs[0].toMap()
-	}
-
-	q, err := s.newQuery(t, opts)
-	if err != nil {
-		return nil, err
-	}
-
-	return s.Query(q)
+	return t.RunContext(context.Background(), s, optArgs...)
 }
 
 // RunWrite runs a query using the given connection but unlike Run automatically
@@ -255,25 +266,11 @@ func (t Term) Run(s *Session, optArgs ...RunOpts) (*Cursor, error) {
 //
 // If an error occurs when running the write query the first error is returned.
 //
+// RunWrite is a thin wrapper around RunWriteContext using context.Background.
+//
 //	res, err := r.DB("database").Table("table").Insert(doc).RunWrite(sess)
 func (t Term) RunWrite(s *Session, optArgs ...RunOpts) (WriteResponse, error) {
-	var response WriteResponse
-
-	res, err := t.Run(s, optArgs...)
-	if err != nil {
-		return response, err
-	}
-	defer res.Close()
-
-	if err = res.One(&response); err != nil {
-		return response, err
-	}
-
-	if response.Errors > 0 {
-		return response, fmt.Errorf("%s", response.FirstError)
-	}
-
-	return response, nil
+	return t.RunWriteContext(context.Background(), s, optArgs...)
 }
 
 // ExecOpts contains the optional arguments for the Exec function and  inherits
@@ -309,19 +306,11 @@ func (o *ExecOpts) toMap() map[string]interface{} {
 // Exec runs the query but does not return the result. Exec will still wait for
 // the response to be received unless the NoReply field is true.
 //
+// Exec is a thin wrapper around ExecContext using context.Background.
+//
 //	err := r.DB("database").Table("table").Insert(doc).Exec(sess, r.ExecOpts{
 //		NoReply: true,
 //	})
 func (t Term) Exec(s *Session, optArgs ...ExecOpts) error {
-	opts := map[string]interface{}{}
-	if len(optArgs) >= 1 {
-		opts = optArgs[0].toMap()
-	}
-
-	q, err := s.newQuery(t, opts)
-	if err != nil {
-		return err
-	}
-
-	return s.Exec(q)
+	return t.ExecContext(context.Background(), s, optArgs...)
 }
@@ -0,0 +1,121 @@
+package gorethink
+
+import (
+	"context"
+	"time"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// QueryHook is a middleware hook invoked around every query run through
+// Term.Run, Term.RunWrite and Term.Exec. Hooks are registered on a Session
+// with AddQueryHook and are run in FIFO order before the query is sent and
+// in LIFO order once a response has been received, similar to the "onion"
+// model used by HTTP middleware chains.
+//
+// BeforeQuery returns a context which is passed to the next hook in the
+// chain and eventually to AfterQuery, allowing hooks to stash request-scoped
+// values (such as a tracing span) for later use.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, ev *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, ev *QueryEvent)
+}
+
+// QueryEvent describes a single query as it is observed by a QueryHook. It is
+// populated before BeforeQuery is called and updated with the remaining
+// fields before AfterQuery is called.
+type QueryEvent struct {
+	// Token is the query's token as assigned by Session.newQuery.
+	Token int64
+	// Type is the name of the query's type as given by p.Query_QueryType's
+	// String method, e.g. "START" or "STOP".
+	Type string
+	// Opts is the optional arguments map sent alongside the query.
+	Opts map[string]interface{}
+	// Err is the error returned by running the query, if any.
+	Err error
+	// WriteResponse holds the decoded write response for write queries, it
+	// is nil for read-only queries or if decoding failed.
+	WriteResponse *WriteResponse
+	// StartTime and EndTime bound the query, Elapsed is EndTime.Sub(StartTime).
+	StartTime time.Time
+	EndTime   time.Time
+
+	term *Term
+}
+
+// String returns the ReQL representation of the query's term. It is computed
+// lazily so that hooks which do not log the query avoid the cost of
+// formatting it.
+func (ev *QueryEvent) String() string {
+	if ev.term == nil {
+		return ""
+	}
+
+	return ev.term.String()
+}
+
+// Elapsed returns the time taken to run the query, it is only meaningful once
+// AfterQuery has been called.
+func (ev *QueryEvent) Elapsed() time.Duration {
+	return ev.EndTime.Sub(ev.StartTime)
+}
+
+// AddQueryHook registers a QueryHook on the session. Hooks run in the order
+// they were added before a query is sent and in reverse order once the first
+// response has been received. AddQueryHook is safe to call concurrently with
+// queries being run on the same session.
+func (s *Session) AddQueryHook(h QueryHook) {
+	s.hooksMu.Lock()
+	s.queryHooks = append(s.queryHooks, h)
+	s.hooksMu.Unlock()
+}
+
+// hooks returns a snapshot of the hooks currently registered on s, safe to
+// range over without holding s.hooksMu.
+func (s *Session) hooks() []QueryHook {
+	s.hooksMu.Lock()
+	defer s.hooksMu.Unlock()
+
+	if len(s.queryHooks) == 0 {
+		return nil
+	}
+
+	hooks := make([]QueryHook, len(s.queryHooks))
+	copy(hooks, s.queryHooks)
+	return hooks
+}
+
+// runBeforeQueryHooks runs the registered hooks in FIFO order and returns the
+// context produced by the last hook in the chain.
+func (s *Session) runBeforeQueryHooks(ctx context.Context, ev *QueryEvent) context.Context {
+	for _, h := range s.hooks() {
+		ctx = h.BeforeQuery(ctx, ev)
+	}
+
+	return ctx
+}
+
+// runAfterQueryHooks runs the registered hooks in LIFO order.
+func (s *Session) runAfterQueryHooks(ctx context.Context, ev *QueryEvent) {
+	hooks := s.hooks()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, ev)
+	}
+}
+
+// isWriteQuery reports whether t is an Insert, Update, Replace or Delete
+// term, i.e. one whose first result is a WriteResponse rather than an
+// ordinary document.
+func isWriteQuery(t *Term) bool {
+	if t == nil {
+		return false
+	}
+
+	switch t.termType {
+	case p.Term_INSERT, p.Term_UPDATE, p.Term_REPLACE, p.Term_DELETE:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,154 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+type recordingHook struct {
+	name   string
+	trace  *[]string
+	events *[]*QueryEvent
+}
+
+func (h recordingHook) BeforeQuery(ctx context.Context, ev *QueryEvent) context.Context {
+	*h.trace = append(*h.trace, h.name+":before")
+	return ctx
+}
+
+func (h recordingHook) AfterQuery(ctx context.Context, ev *QueryEvent) {
+	*h.trace = append(*h.trace, h.name+":after")
+	*h.events = append(*h.events, ev)
+}
+
+// TestQueryHookOrdering verifies that BeforeQuery hooks run in FIFO order and
+// AfterQuery hooks run in LIFO order, the "onion" ordering documented on
+// QueryHook.
+func TestQueryHookOrdering(t *testing.T) {
+	server, conn := newFakeServer()
+	defer conn.Close()
+
+	sess := &Session{conn: conn}
+
+	var trace []string
+	var events []*QueryEvent
+	sess.AddQueryHook(recordingHook{name: "a", trace: &trace, events: &events})
+	sess.AddQueryHook(recordingHook{name: "b", trace: &trace, events: &events})
+
+	go func() {
+		frame, err := server.readFrame()
+		if err != nil {
+			return
+		}
+		_ = server.writeResponse(frame.token, rawResponse{Type: responseTypeSuccessSequence, Results: []json.RawMessage{json.RawMessage(`1`)}})
+	}()
+
+	q := Query{Type: p.Query_START, Token: 1, Term: &Term{termType: p.Term_TABLE}}
+	if _, err := sess.QueryContext(context.Background(), q); err != nil {
+		t.Fatalf("unexpected error from QueryContext: %v", err)
+	}
+
+	want := []string{"a:before", "b:before", "b:after", "a:after"}
+	if len(trace) != len(want) {
+		t.Fatalf("expected trace %v, got %v", want, trace)
+	}
+	for i := range want {
+		if trace[i] != want[i] {
+			t.Fatalf("expected trace %v, got %v", want, trace)
+		}
+	}
+}
+
+// TestQueryHookWriteResponsePopulated guards against QueryEvent.WriteResponse
+// being documented but never assigned: for a write query the first result
+// (a WriteResponse) must be decoded into ev.WriteResponse before AfterQuery
+// fires, without consuming it out from under RunWriteContext's own read.
+func TestQueryHookWriteResponsePopulated(t *testing.T) {
+	server, conn := newFakeServer()
+	defer conn.Close()
+
+	sess := &Session{conn: conn}
+
+	var trace []string
+	var events []*QueryEvent
+	sess.AddQueryHook(recordingHook{name: "a", trace: &trace, events: &events})
+
+	go func() {
+		frame, err := server.readFrame()
+		if err != nil {
+			return
+		}
+		body, _ := json.Marshal(WriteResponse{Inserted: 1, GeneratedKeys: []string{"abc"}})
+		_ = server.writeResponse(frame.token, rawResponse{Type: responseTypeSuccessAtom, Results: []json.RawMessage{json.RawMessage(body)}})
+	}()
+
+	insertTerm := Term{
+		termType: p.Term_INSERT,
+		args: []Term{
+			{termType: p.Term_TABLE, name: "Table"},
+			{termType: p.Term_MAKE_ARRAY},
+		},
+	}
+	q := Query{Type: p.Query_START, Token: 1, Term: &insertTerm}
+
+	cur, err := sess.QueryContext(context.Background(), q)
+	if err != nil {
+		t.Fatalf("unexpected error from QueryContext: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 AfterQuery event, got %d", len(events))
+	}
+	if events[0].WriteResponse == nil {
+		t.Fatal("expected WriteResponse to be populated for a write query")
+	}
+	if events[0].WriteResponse.Inserted != 1 {
+		t.Fatalf("expected Inserted to be 1, got %d", events[0].WriteResponse.Inserted)
+	}
+
+	// The peek used to populate WriteResponse must not have consumed the
+	// result: the caller's own read should still see it.
+	var wr WriteResponse
+	if !cur.NextContext(context.Background(), &wr) {
+		t.Fatalf("expected the cursor to still yield its one result, got err: %v", cur.Err())
+	}
+	if wr.Inserted != 1 {
+		t.Fatalf("expected Inserted to be 1, got %d", wr.Inserted)
+	}
+}
+
+// TestQueryHookWriteResponseNilForReads checks that read-only queries never
+// populate WriteResponse.
+func TestQueryHookWriteResponseNilForReads(t *testing.T) {
+	server, conn := newFakeServer()
+	defer conn.Close()
+
+	sess := &Session{conn: conn}
+
+	var trace []string
+	var events []*QueryEvent
+	sess.AddQueryHook(recordingHook{name: "a", trace: &trace, events: &events})
+
+	go func() {
+		frame, err := server.readFrame()
+		if err != nil {
+			return
+		}
+		_ = server.writeResponse(frame.token, rawResponse{Type: responseTypeSuccessSequence, Results: []json.RawMessage{json.RawMessage(`1`)}})
+	}()
+
+	q := Query{Type: p.Query_START, Token: 1, Term: &Term{termType: p.Term_TABLE}}
+	if _, err := sess.QueryContext(context.Background(), q); err != nil {
+		t.Fatalf("unexpected error from QueryContext: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 AfterQuery event, got %d", len(events))
+	}
+	if events[0].WriteResponse != nil {
+		t.Fatal("expected WriteResponse to stay nil for a read-only query")
+	}
+}
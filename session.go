@@ -0,0 +1,120 @@
+package gorethink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	p "gopkg.in/dancannon/gorethink.v1/ql2"
+)
+
+// Session represents a connection to a RethinkDB server and is used to run
+// queries via Term.Run, Term.RunWrite and Term.Exec.
+type Session struct {
+	conn *Connection
+
+	token int64
+
+	hooksMu    sync.Mutex
+	queryHooks []QueryHook
+}
+
+// newQuery builds a Query for t, assigning it a fresh token used to
+// correlate the query with its response. newQuery is a thin wrapper around
+// newQueryContext using context.Background.
+func (s *Session) newQuery(t Term, opts map[string]interface{}) (Query, error) {
+	return s.newQueryContext(context.Background(), t, opts)
+}
+
+// newQueryContext is the context-aware equivalent of newQuery.
+func (s *Session) newQueryContext(ctx context.Context, t Term, opts map[string]interface{}) (Query, error) {
+	builtTerm, err := t.build()
+	if err != nil {
+		return Query{}, err
+	}
+
+	q := Query{
+		Type:      p.Query_START,
+		Token:     atomic.AddInt64(&s.token, 1),
+		Term:      &t,
+		Opts:      opts,
+		builtTerm: builtTerm,
+	}
+
+	return q, nil
+}
+
+// Query sends q to the server and returns a Cursor over the results. Query
+// is a thin wrapper around QueryContext using context.Background.
+func (s *Session) Query(q Query) (*Cursor, error) {
+	return s.QueryContext(context.Background(), q)
+}
+
+// QueryContext sends q to the server and returns a Cursor over the results.
+// If ctx is done before the query completes, a STOP is sent for q.Token and
+// the wait is unblocked, with QueryContext returning ctx.Err(). Any
+// QueryHooks registered on s via AddQueryHook are invoked before the query
+// is sent and after the first response is received. For a write query
+// (Insert, Update, Replace or Delete) the first result is peeked and decoded
+// into QueryEvent.WriteResponse before AfterQuery runs, without consuming it,
+// so RunWriteContext's own read of the cursor is unaffected.
+func (s *Session) QueryContext(ctx context.Context, q Query) (*Cursor, error) {
+	ev := &QueryEvent{
+		Token:     q.Token,
+		Type:      q.Type.String(),
+		Opts:      q.Opts,
+		StartTime: time.Now(),
+		term:      q.Term,
+	}
+
+	ctx = s.runBeforeQueryHooks(ctx, ev)
+
+	cur, err := s.conn.sendQuery(ctx, q)
+
+	if err == nil && isWriteQuery(q.Term) {
+		if raw, ok := cur.peekFirstRaw(); ok {
+			var wr WriteResponse
+			if json.Unmarshal(raw, &wr) == nil {
+				ev.WriteResponse = &wr
+			}
+		}
+	}
+
+	ev.EndTime = time.Now()
+	ev.Err = err
+	s.runAfterQueryHooks(ctx, ev)
+
+	return cur, err
+}
+
+// Exec sends q to the server and, unless q.Opts sets noreply, waits for it to
+// be acknowledged, discarding the result. Exec is a thin wrapper around ExecContext using
+// context.Background.
+func (s *Session) Exec(q Query) error {
+	return s.ExecContext(context.Background(), q)
+}
+
+// ExecContext is the context-aware equivalent of Exec. If q.Opts sets
+// noreply to true the query is only written to the connection, after running
+// the QueryHooks around the write, and ExecContext returns without waiting
+// for a response.
+func (s *Session) ExecContext(ctx context.Context, q Query) error {
+	if noreply, _ := q.Opts["noreply"].(bool); noreply {
+		ev := &QueryEvent{Token: q.Token, Type: q.Type.String(), Opts: q.Opts, StartTime: time.Now(), term: q.Term}
+		ctx = s.runBeforeQueryHooks(ctx, ev)
+		err := s.conn.write(q)
+		ev.EndTime = time.Now()
+		ev.Err = err
+		s.runAfterQueryHooks(ctx, ev)
+		return err
+	}
+
+	cur, err := s.QueryContext(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	return cur.Close()
+}